@@ -0,0 +1,27 @@
+package cache
+
+// ByteView 持有缓存值的一份不可变拷贝，对外只读。
+type ByteView struct {
+	b []byte
+}
+
+// Len 实现 lru.Value 接口。
+func (v ByteView) Len() int {
+	return len(v.b)
+}
+
+// ByteSlice 返回底层数据的拷贝，防止调用方修改缓存内容。
+func (v ByteView) ByteSlice() []byte {
+	return cloneBytes(v.b)
+}
+
+// String 以字符串形式返回数据，必要时会产生拷贝。
+func (v ByteView) String() string {
+	return string(v.b)
+}
+
+func cloneBytes(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
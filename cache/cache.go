@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"Cache/cache/lru"
+	"sync"
+	"time"
+)
+
+// CacheStats 记录单个缓存实例（mainCache 或 hotCache）的运行时指标。
+type CacheStats struct {
+	Bytes     int64
+	Items     int64
+	Gets      int64
+	Hits      int64
+	Evictions int64
+}
+
+// cache 是对 lru.Cache 的并发安全封装，附带命中/淘汰统计。
+type cache struct {
+	mu         sync.Mutex
+	lru        *lru.Cache
+	cacheBytes int64
+
+	nget   int64
+	nhit   int64
+	nevict int64
+}
+
+// add 写入 key/value，ttl 为 0 表示不设置过期时间。
+func (c *cache) add(key string, value ByteView, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil {
+		c.lru = lru.New(c.cacheBytes, func(key string, value lru.Value) {
+			c.nevict++
+		})
+	}
+	if ttl > 0 {
+		c.lru.AddWithExpire(key, value, time.Now().Add(ttl))
+	} else {
+		c.lru.Add(key, value)
+	}
+}
+
+func (c *cache) get(key string) (value ByteView, ok bool) {
+	value, _, ok = c.getWithExpire(key)
+	return
+}
+
+// getWithExpire 与 get 相同，但额外返回该记录的过期时间（零值表示不过期），
+// 供需要把剩余 TTL 转发给对端的场景使用（见 Group.getWithExpire）。
+func (c *cache) getWithExpire(key string) (value ByteView, expireAt time.Time, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nget++
+	if c.lru == nil {
+		return
+	}
+	if v, exp, ok := c.lru.GetWithExpire(key); ok {
+		c.nhit++
+		return v.(ByteView), exp, true
+	}
+	return
+}
+
+// remove 主动删除 key 对应的记录（若存在），用于 Group.Remove 的失效广播。
+func (c *cache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru != nil {
+		c.lru.Remove(key)
+	}
+}
+
+// removeOldest 淘汰该缓存中最久未使用的一条记录，供跨缓存的淘汰策略调用。
+func (c *cache) removeOldest() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru != nil {
+		c.lru.RemoveOldest()
+	}
+}
+
+// bytes 返回该缓存当前占用的字节数。
+func (c *cache) bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil {
+		return 0
+	}
+	return c.lru.Bytes()
+}
+
+func (c *cache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := CacheStats{
+		Gets:      c.nget,
+		Hits:      c.nhit,
+		Evictions: c.nevict,
+	}
+	if c.lru != nil {
+		stats.Bytes = c.lru.Bytes()
+		stats.Items = int64(c.lru.Len())
+	}
+	return stats
+}
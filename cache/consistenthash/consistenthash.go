@@ -25,13 +25,18 @@ type Map struct {
 	replicates int
 	keys       []int
 	hashMap    map[int]string
+	// nodeReplicas 记录每个真实节点实际创建了多少个虚拟节点，
+	// Add 使用默认的 replicates，AddWeighted 可以按权重覆盖它，
+	// Remove 据此知道要清理多少个虚拟节点。
+	nodeReplicas map[string]int
 }
 
 func New(replicats int, fn Hash) *Map {
 	m := &Map{
-		replicates: replicats,
-		hash:       fn,
-		hashMap:    make(map[int]string),
+		replicates:   replicats,
+		hash:         fn,
+		hashMap:      make(map[int]string),
+		nodeReplicas: make(map[string]int),
 	}
 	if m.hash == nil {
 		// 返回crc-32校验 使用IEEE多项式 默认算法
@@ -40,21 +45,85 @@ func New(replicats int, fn Hash) *Map {
 	return m
 }
 
-// Add 函数允许传入 0 或 多个真实节点的名称。
-// 对每一个真实节点 key，对应创建 m.replicas 个虚拟节点，
+// Add 函数允许传入 0 或 多个真实节点的名称，按默认的 replicates 添加虚拟节点。
+func (m *Map) Add(keys ...string) {
+	for _, key := range keys {
+		m.addReplicas(key, m.replicates)
+	}
+}
+
+// AddWeighted 按 weight 为 key 创建 replicates*weight 个虚拟节点，
+// 使配置更高的节点在环上获得成比例更多的虚拟节点，从而分担更多的 key。
+// weight <= 0 时退化为权重 1，与 Add 等价。
+func (m *Map) AddWeighted(key string, weight int) {
+	if weight <= 0 {
+		weight = 1
+	}
+	m.addReplicas(key, m.replicates*weight)
+}
+
+// addReplicas 对每一个真实节点 key，对应创建 replicas 个虚拟节点，
 // 虚拟节点的名称是：strconv.Itoa(i) + key，即通过添加编号的方式区分不同虚拟节点。
 // 使用 m.hash() 计算虚拟节点的哈希值，使用 append(m.keys, hash) 添加到环上。
 // 在 hashMap 中增加虚拟节点和真实节点的映射关系。
+// key 若之前已经添加过（例如先 Add 再用 AddWeighted 重新设置权重），先把旧的
+// 虚拟节点清理掉，否则低位索引会与旧批次的哈希重复，在 m.keys 中产生再也无法
+// 通过 Remove 完全回收的重复槽位。
 // 最后一步，环上的哈希值排序。
-func (m *Map) Add(keys ...string) {
+func (m *Map) addReplicas(key string, replicas int) {
+	m.removeReplicas(key)
+	for i := 0; i < replicas; i++ {
+		hash := int(m.hash([]byte(strconv.Itoa(i) + key)))
+		m.keys = append(m.keys, hash)
+		m.hashMap[hash] = key
+	}
+	m.nodeReplicas[key] = replicas
+	sort.Ints(m.keys)
+}
+
+// Remove 清理 key 对应的全部虚拟节点。
+func (m *Map) Remove(keys ...string) {
 	for _, key := range keys {
-		for i := 0; i < m.replicates; i++ {
-			hash := int(m.hash([]byte(strconv.Itoa(i) + key)))
-			m.keys = append(m.keys, hash)
-			m.hashMap[hash] = key
+		m.removeReplicas(key)
+	}
+}
+
+// removeReplicas 清理 key 当前持有的全部虚拟节点（若有）。若某个虚拟节点的
+// 哈希槽位恰好与另一个真实节点的虚拟节点相同（哈希碰撞），该槽位此时由
+// hashMap 记录的是后添加者，只有当 hashMap 中该槽位仍然属于 key 时才会删除，
+// 避免误删仍在使用中的节点。
+func (m *Map) removeReplicas(key string) {
+	replicas, ok := m.nodeReplicas[key]
+	if !ok {
+		return
+	}
+	for i := 0; i < replicas; i++ {
+		hash := int(m.hash([]byte(strconv.Itoa(i) + key)))
+		if m.hashMap[hash] != key {
+			continue
+		}
+		delete(m.hashMap, hash)
+		m.removeRingKey(hash)
+		// 环上仍留有同一槽位的副本时，说明另一个真实节点的虚拟节点也落在这里，
+		// 把该槽位交还给它，而不是留下一个没有映射的槽位。
+		if idx := sort.SearchInts(m.keys, hash); idx < len(m.keys) && m.keys[idx] == hash {
+			for other, n := range m.nodeReplicas {
+				for j := 0; other != key && j < n; j++ {
+					if int(m.hash([]byte(strconv.Itoa(j)+other))) == hash {
+						m.hashMap[hash] = other
+					}
+				}
+			}
 		}
 	}
-	sort.Ints(m.keys)
+	delete(m.nodeReplicas, key)
+}
+
+func (m *Map) removeRingKey(hash int) {
+	idx := sort.SearchInts(m.keys, hash)
+	if idx < len(m.keys) && m.keys[idx] == hash {
+		m.keys = append(m.keys[:idx], m.keys[idx+1:]...)
+	}
 }
 
 func (m *Map) Get(key string) string {
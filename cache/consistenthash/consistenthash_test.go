@@ -0,0 +1,84 @@
+package consistenthash
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestGetBasic(t *testing.T) {
+	hash := New(3, func(key []byte) uint32 {
+		n, _ := strconv.Atoi(string(key))
+		return uint32(n)
+	})
+
+	hash.Add("6", "4", "2")
+
+	testCases := map[string]string{
+		"2":  "2",
+		"11": "2",
+		"23": "4",
+		"27": "2",
+	}
+	for k, want := range testCases {
+		if got := hash.Get(k); got != want {
+			t.Errorf("Get(%q) = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	hash := New(3, func(key []byte) uint32 {
+		n, _ := strconv.Atoi(string(key))
+		return uint32(n)
+	})
+	hash.Add("6", "4", "2")
+
+	hash.Remove("6")
+
+	if got := hash.Get("23"); got == "6" {
+		t.Errorf("Get(23) still resolves to removed peer 6")
+	}
+	if _, ok := hash.nodeReplicas["6"]; ok {
+		t.Errorf("nodeReplicas still tracks removed peer 6")
+	}
+}
+
+func TestAddWeightedGivesProportionallyMoreVirtualNodes(t *testing.T) {
+	base := New(10, nil)
+	base.Add("peerA")
+	baseline := base.nodeReplicas["peerA"]
+
+	weighted := New(10, nil)
+	weighted.AddWeighted("peerB", 5)
+
+	if got, want := weighted.nodeReplicas["peerB"], baseline*5; got != want {
+		t.Errorf("AddWeighted(peerB, 5) created %d virtual nodes, want %d", got, want)
+	}
+}
+
+// TestRemoveAfterReweight reproduces the reported bug: re-adding a key that is
+// already present (e.g. calling AddWeighted to reweight a peer originally
+// added via Add, which is exactly the heterogeneous-peers use case this type
+// exists for) must fully replace its previous virtual nodes. Otherwise the
+// low-index virtual nodes collide with the earlier batch's hashes, get
+// inserted twice into the ring, and Remove can only reclaim one copy,
+// leaving an orphaned slot that a valid remaining peer can no longer serve.
+func TestRemoveAfterReweight(t *testing.T) {
+	hash := New(50, nil)
+	hash.Add("peerA")
+	hash.Add("peerB")
+	hash.AddWeighted("peerA", 5)
+	hash.Remove("peerA")
+
+	if _, ok := hash.nodeReplicas["peerA"]; ok {
+		t.Fatalf("nodeReplicas still tracks removed peer peerA")
+	}
+
+	const numKeys = 1000
+	for i := 0; i < numKeys; i++ {
+		key := strconv.Itoa(i)
+		if got := hash.Get(key); got != "peerB" {
+			t.Fatalf("Get(%q) = %q, want %q (peerB is the only remaining peer)", key, got, "peerB")
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package discovery
+
+// PeerRegistry 是缓存节点向服务注册中心登记自己、并订阅对端节点变化的抽象，
+// 使 HTTPPool（及未来的其他 Pool 实现）可以摆脱手工调用 Set 维护静态节点列表，
+// 变成节点上下线自动感知的自愈集群。
+type PeerRegistry interface {
+	// Register 把 self 注册到注册中心，并负责在后台维持租约存活；
+	// 进程退出或失联后，注册中心应当在租约过期后自动清理该节点。
+	Register(self string) error
+	// Watch 返回一个 channel，每当节点集合发生变化时推送最新的完整节点列表。
+	Watch() <-chan []string
+}
@@ -0,0 +1,120 @@
+package discovery
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	registryPrefix = "/cache/peers/"
+	leaseTTLSecond = 10
+)
+
+// EtcdRegistry 是 PeerRegistry 的 etcd 实现：每个节点以
+// registryPrefix+self 为 key 注册自己，并绑定一个定期续约的 lease 维持存活；
+// 一旦进程退出或失联，lease 过期后 etcd 会自动删除该 key，其他节点通过 Watch
+// 感知到这次下线，新节点 Put 时也会触发 Watch，从而自动开始分担流量。
+type EtcdRegistry struct {
+	client *clientv3.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	ch     chan []string
+}
+
+// NewEtcdRegistry 使用给定的 etcd endpoints 创建一个 EtcdRegistry。
+func NewEtcdRegistry(endpoints []string) (*EtcdRegistry, error) {
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &EtcdRegistry{
+		client: cli,
+		ctx:    ctx,
+		cancel: cancel,
+		ch:     make(chan []string, 1),
+	}, nil
+}
+
+// Register 把 self 写入 etcd 并绑定一个 TTL 为 leaseTTLSecond 秒的 lease，
+// 随后在后台持续续约，直到 Close 被调用或进程退出导致续约中断。
+func (r *EtcdRegistry) Register(self string) error {
+	lease, err := r.client.Grant(r.ctx, leaseTTLSecond)
+	if err != nil {
+		return err
+	}
+	key := registryPrefix + self
+	if _, err := r.client.Put(r.ctx, key, self, clientv3.WithLease(lease.ID)); err != nil {
+		return err
+	}
+	keepAlive, err := r.client.KeepAlive(r.ctx, lease.ID)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for {
+			select {
+			case <-r.ctx.Done():
+				return
+			case _, ok := <-keepAlive:
+				if !ok {
+					log.Printf("[discovery] lease for %s expired", self)
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Watch 先全量拉取一次当前节点集合，再持续 watch registryPrefix 前缀下的
+// 变化；每当有节点加入或离开，都会把排序后的完整节点列表推送到返回的 channel。
+func (r *EtcdRegistry) Watch() <-chan []string {
+	go r.watch()
+	return r.ch
+}
+
+func (r *EtcdRegistry) watch() {
+	// Close 取消 ctx 后关闭 channel，让 range 该 channel 的调用方（如
+	// HTTPPool.RunWithRegistry）得以返回。
+	defer close(r.ch)
+	r.publish()
+	rch := r.client.Watch(r.ctx, registryPrefix, clientv3.WithPrefix())
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-rch:
+			r.publish()
+		}
+	}
+}
+
+func (r *EtcdRegistry) publish() {
+	resp, err := r.client.Get(r.ctx, registryPrefix, clientv3.WithPrefix())
+	if err != nil {
+		log.Printf("[discovery] list peers failed: %v", err)
+		return
+	}
+	peers := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		peers = append(peers, string(kv.Value))
+	}
+	sort.Strings(peers)
+	r.ch <- peers
+}
+
+// Close 停止续约和 watch，并释放底层的 etcd 客户端连接。
+func (r *EtcdRegistry) Close() error {
+	r.cancel()
+	return r.client.Close()
+}
+
+var _ PeerRegistry = (*EtcdRegistry)(nil)
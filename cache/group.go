@@ -0,0 +1,250 @@
+package cache
+
+import (
+	pb "Cache/cache/cachepb/cachepb"
+	"Cache/cache/singleflight"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// hotCacheMirrorChance 是从远程节点取回的值被额外写入 hotCache 的概率，
+// 取 1/10 即可在不显著增加内存占用的前提下分摊热点 key 的回源压力。
+const hotCacheMirrorChance = 10
+
+// hotCacheRatio 限制 hotCache 相对 mainCache 的大小：当 hotCache 占用的字节数
+// 超过 mainCache 的 1/8 时，优先从 hotCache 淘汰，避免热点镜像挤占本该属于
+// mainCache 的配额。
+const hotCacheRatio = 8
+
+// Getter 用于在缓存未命中时加载数据源。
+type Getter interface {
+	Get(key string) ([]byte, error)
+}
+
+// GetterFunc 是 Getter 的函数适配器。
+type GetterFunc func(key string) ([]byte, error)
+
+func (f GetterFunc) Get(key string) ([]byte, error) {
+	return f(key)
+}
+
+// TTLGetter 是 Getter 的一个可选扩展：除了值本身，还返回该值应当存活的时长，
+// 0 表示不过期。Getter 实现了这个接口时，Group 会优先用它加载数据并把返回的
+// ttl 一并写入 mainCache，从而在纯 LRU 淘汰之外再获得一个有界陈旧度的失效手段。
+type TTLGetter interface {
+	GetWithTTL(key string) (value []byte, ttl time.Duration, err error)
+}
+
+// TTLGetterFunc 是 TTLGetter 的函数适配器。
+type TTLGetterFunc func(key string) ([]byte, time.Duration, error)
+
+func (f TTLGetterFunc) GetWithTTL(key string) ([]byte, time.Duration, error) {
+	return f(key)
+}
+
+// Group 是一个缓存命名空间，关联一个 Getter 和底层的两级缓存：
+// mainCache 保存本节点通过一致性哈希拥有的 key，hotCache 额外镜像从其他
+// 节点取回的热点 key，二者共享 cacheBytes 这一字节预算。
+type Group struct {
+	name       string
+	getter     Getter
+	mainCache  cache
+	hotCache   cache
+	cacheBytes int64
+	peers      PeerPicker
+	loader     *singleflight.Group
+}
+
+var (
+	mu     sync.RWMutex
+	groups = make(map[string]*Group)
+)
+
+// NewGroup 创建一个 Group 并注册到全局，cacheBytes 是 mainCache 与 hotCache
+// 共享的总字节预算。
+func NewGroup(name string, cacheBytes int64, getter Getter) *Group {
+	if getter == nil {
+		panic("nil Getter")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	g := &Group{
+		name:       name,
+		getter:     getter,
+		cacheBytes: cacheBytes,
+		mainCache:  cache{cacheBytes: cacheBytes},
+		hotCache:   cache{cacheBytes: cacheBytes},
+		loader:     &singleflight.Group{},
+	}
+	groups[name] = g
+	return g
+}
+
+// GetGroup 按名称查找之前创建的 Group，不存在时返回 nil。
+func GetGroup(name string) *Group {
+	mu.RLock()
+	defer mu.RUnlock()
+	return groups[name]
+}
+
+// RegisterPeers 为 Group 注册 PeerPicker，只能调用一次。
+func (g *Group) RegisterPeers(peers PeerPicker) {
+	if g.peers != nil {
+		panic("RegisterPeerPicker called more than once")
+	}
+	g.peers = peers
+}
+
+// Get 依次查询 mainCache、hotCache，都未命中时触发 load。
+func (g *Group) Get(key string) (ByteView, error) {
+	v, _, err := g.getWithExpire(key)
+	return v, err
+}
+
+// getWithExpire 与 Get 相同，但额外返回该 key 剩余的 TTL（0 表示没有设置
+// 过期时间）。HTTPPool/GrpcPool 在代表本节点应答其他节点的请求时使用它，
+// 把剩余 TTL 一并写进 pb.Response，这样对端把结果镜像进 hotCache 时，
+// 才不会把一个有过期时间的值当成永不过期来缓存。
+func (g *Group) getWithExpire(key string) (ByteView, time.Duration, error) {
+	if key == "" {
+		return ByteView{}, 0, fmt.Errorf("key is required")
+	}
+	if v, expireAt, ok := g.mainCache.getWithExpire(key); ok {
+		log.Println("[Cache] hit")
+		return v, remainingTTL(expireAt), nil
+	}
+	if v, expireAt, ok := g.hotCache.getWithExpire(key); ok {
+		log.Println("[Cache] hot hit")
+		return v, remainingTTL(expireAt), nil
+	}
+	return g.load(key)
+}
+
+// remainingTTL 把一个绝对过期时间换算成从现在起还剩多久，零值或已过期都
+// 返回 0（表示不附带 TTL 信息 / 即将被当作未命中）。
+func remainingTTL(expireAt time.Time) time.Duration {
+	if expireAt.IsZero() {
+		return 0
+	}
+	if d := time.Until(expireAt); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Stats 返回 mainCache、hotCache 各自的运行时指标。
+func (g *Group) Stats() (main, hot CacheStats) {
+	return g.mainCache.stats(), g.hotCache.stats()
+}
+
+// Remove 清除 key 在本节点 mainCache、hotCache 中的记录。如果注册的 PeerPicker
+// 同时实现了 PeerBroadcaster，还会把这次删除尽力广播给其余所有节点，使它们
+// 也清理各自的本地缓存；返回值按节点地址汇总每个节点各自的失败原因（没有
+// PeerBroadcaster 时为 nil）。这解除了此前“一次写入、永不更新”的限制。
+func (g *Group) Remove(key string) map[string]error {
+	g.removeLocally(key)
+	if broadcaster, ok := g.peers.(PeerBroadcaster); ok {
+		return broadcaster.Broadcast(g.name, key)
+	}
+	return nil
+}
+
+// removeLocally 只清理本节点的缓存，供收到广播删除请求时调用，避免无限重复广播。
+func (g *Group) removeLocally(key string) {
+	g.mainCache.remove(key)
+	g.hotCache.remove(key)
+}
+
+// loadResult 是 load 内部 singleflight.Do 的返回值：把 value 和它被写入缓存
+// 时的剩余 ttl 一起传出去，这样等待者能直接拿到 ttl，不需要再探一次缓存
+// （探测本身会被 cache.getWithExpire 计入 Gets/Hits，污染 CacheStats）。
+type loadResult struct {
+	value ByteView
+	ttl   time.Duration
+}
+
+// load 通过 singleflight 合并针对同一个 key 的并发加载：无论最终是从远程节点
+// 取回还是回源到 getter，同一时刻同一个 key 只会真正执行一次。
+func (g *Group) load(key string) (value ByteView, ttl time.Duration, err error) {
+	resi, err := g.loader.Do(key, func() (interface{}, error) {
+		if g.peers != nil {
+			if peer, ok := g.peers.PickPeer(key); ok {
+				value, ttl, err := g.getFromPeer(peer, key)
+				if err == nil {
+					return loadResult{value, ttl}, nil
+				}
+				log.Println("[Cache] Failed to get from peer", err)
+			}
+		}
+		value, ttl, err := g.getLocally(key)
+		if err != nil {
+			return nil, err
+		}
+		return loadResult{value, ttl}, nil
+	})
+	if err != nil {
+		return ByteView{}, 0, err
+	}
+	res := resi.(loadResult)
+	return res.value, res.ttl, nil
+}
+
+// getLocally 是本节点对该 key 拥有权威数据时的加载路径，结果写入 mainCache。
+// 如果 getter 实现了 TTLGetter，取回的 ttl 会一并写入，到期后该 key 在
+// mainCache 中即被当作未命中，从而触发 singleflight 守护下的重新加载。
+func (g *Group) getLocally(key string) (ByteView, time.Duration, error) {
+	var bytes []byte
+	var ttl time.Duration
+	var err error
+	if ttlGetter, ok := g.getter.(TTLGetter); ok {
+		bytes, ttl, err = ttlGetter.GetWithTTL(key)
+	} else {
+		bytes, err = g.getter.Get(key)
+	}
+	if err != nil {
+		return ByteView{}, 0, err
+	}
+	value := ByteView{b: cloneBytes(bytes)}
+	g.populateCache(key, value, &g.mainCache, ttl)
+	return value, ttl, nil
+}
+
+// getFromPeer 从远程节点取回 key 对应的值，并以 1/hotCacheMirrorChance 的
+// 概率将其镜像进 hotCache，分摊该远程节点后续被重复访问的压力。
+func (g *Group) getFromPeer(peer PeerGetter, key string) (ByteView, time.Duration, error) {
+	req := &pb.Request{Group: g.name, Key: key}
+	res := &pb.Response{}
+	err := peer.Get(req, res)
+	if err != nil {
+		return ByteView{}, 0, err
+	}
+	value := ByteView{b: res.Value}
+	ttl := time.Duration(res.GetTtlMillis()) * time.Millisecond
+	if rand.Intn(hotCacheMirrorChance) == 0 {
+		g.populateCache(key, value, &g.hotCache, ttl)
+	}
+	return value, ttl, nil
+}
+
+// populateCache 将 value 写入 dest（mainCache 或 hotCache），ttl 为 0 表示不
+// 设置过期时间，随后执行跨缓存的淘汰策略：只要 mainCache 与 hotCache 的总字节
+// 数超过 cacheBytes，就持续淘汰，且当 hotCache 的占用超过 mainCache 的
+// 1/hotCacheRatio 时优先从 hotCache 淘汰，否则从 mainCache 淘汰。
+func (g *Group) populateCache(key string, value ByteView, dest *cache, ttl time.Duration) {
+	dest.add(key, value, ttl)
+	if g.cacheBytes == 0 {
+		// 与 lru.Cache 的约定一致：0 表示不限制大小，不能让下面的循环把
+		// "total > 0" 误当成"超出预算"，否则第一条记录写入后会被立刻淘汰。
+		return
+	}
+	for g.mainCache.bytes()+g.hotCache.bytes() > g.cacheBytes {
+		victim := &g.mainCache
+		if g.hotCache.bytes() > g.mainCache.bytes()/hotCacheRatio {
+			victim = &g.hotCache
+		}
+		victim.removeOldest()
+	}
+}
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	pb "Cache/cache/cachepb/cachepb"
+	"Cache/cache/consistenthash"
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"log"
+	"net"
+	"sync"
+)
+
+const defaultGrpcReplicate = 50
+
+// GrpcPool 是 PeerPicker 的 gRPC 实现，与 HTTPPool 一样支持选取节点、应答取值
+// 和广播删除，只是把节点间通信换成了 gRPC；它没有 RunWithRegistry，节点列表
+// 只能通过 Set 更新。Group 只依赖 PeerPicker/PeerGetter 接口，
+// 调用方在组网时选择用 HTTPPool 还是 GrpcPool 注册即可，Group 本身的代码
+// 不需要做任何改动。
+type GrpcPool struct {
+	self        string
+	mu          sync.Mutex
+	peers       *consistenthash.Map
+	grpcGetters map[string]*grpcGetter
+}
+
+// NewGrpcPool 创建一个 GrpcPool，self 是本节点的地址（如 "127.0.0.1:8001"）。
+func NewGrpcPool(self string) *GrpcPool {
+	return &GrpcPool{self: self}
+}
+
+func (p *GrpcPool) Log(format string, v ...interface{}) {
+	log.Printf("[Server %s] %s", p.self, fmt.Sprintf(format, v...))
+}
+
+// Set 更新节点列表。与 HTTPPool.Set 一样，只对新增/移除的节点分别调用
+// Add/Remove，而不是重建整个哈希环和全部 grpcGetter，这样一次滚动的成员
+// 变更既只会重新映射大约 1/N 的 key，也不会丢弃未受影响节点正在复用的
+// grpc.ClientConn。
+func (p *GrpcPool) Set(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.peers == nil {
+		p.peers = consistenthash.New(defaultGrpcReplicate, nil)
+		p.grpcGetters = make(map[string]*grpcGetter, len(peers))
+	}
+
+	newSet := make(map[string]struct{}, len(peers))
+	for _, peer := range peers {
+		newSet[peer] = struct{}{}
+	}
+
+	var removed []string
+	for peer := range p.grpcGetters {
+		if _, ok := newSet[peer]; !ok {
+			removed = append(removed, peer)
+		}
+	}
+	if len(removed) > 0 {
+		p.peers.Remove(removed...)
+		for _, peer := range removed {
+			delete(p.grpcGetters, peer)
+		}
+	}
+
+	var added []string
+	for _, peer := range peers {
+		if _, ok := p.grpcGetters[peer]; !ok {
+			added = append(added, peer)
+		}
+	}
+	if len(added) > 0 {
+		p.peers.Add(added...)
+		for _, peer := range added {
+			p.grpcGetters[peer] = &grpcGetter{addr: peer}
+		}
+	}
+}
+
+func (p *GrpcPool) PickPeer(key string) (PeerGetter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if peer := p.peers.Get(key); peer != "" && peer != p.self {
+		p.Log("Pick peer %s", peer)
+		return p.grpcGetters[peer], true
+	}
+	return nil, false
+}
+
+// Serve 在 lis 上启动 gRPC 服务端，对应 HTTPPool.ServeHTTP 的职责：
+// 按 group 名查表，再用 Group.Get 取值返回给调用方。
+func (p *GrpcPool) Serve(lis net.Listener) error {
+	s := grpc.NewServer()
+	pb.RegisterGroupCacheServer(s, &groupCacheServer{pool: p})
+	return s.Serve(lis)
+}
+
+// Broadcast 向除自身外的所有已知节点发送删除请求，采用尽力而为策略：单个
+// 节点超时或失败不影响其余节点的广播，调用方可以从返回值中看到每个节点各自
+// 的结果。
+func (p *GrpcPool) Broadcast(group, key string) map[string]error {
+	p.mu.Lock()
+	getters := make(map[string]*grpcGetter, len(p.grpcGetters))
+	for peer, getter := range p.grpcGetters {
+		if peer == p.self {
+			continue
+		}
+		getters[peer] = getter
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make(map[string]error, len(getters))
+	for peer, getter := range getters {
+		wg.Add(1)
+		go func(peer string, getter *grpcGetter) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), broadcastTimeout)
+			defer cancel()
+			err := getter.delete(ctx, group, key)
+			mu.Lock()
+			errs[peer] = err
+			mu.Unlock()
+		}(peer, getter)
+	}
+	wg.Wait()
+	return errs
+}
+
+var _ PeerPicker = (*GrpcPool)(nil)
+var _ PeerBroadcaster = (*GrpcPool)(nil)
+
+type groupCacheServer struct {
+	pb.UnimplementedGroupCacheServer
+	pool *GrpcPool
+}
+
+func (s *groupCacheServer) Get(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	s.pool.Log("%s %s", req.GetGroup(), req.GetKey())
+	group := GetGroup(req.GetGroup())
+	if group == nil {
+		return nil, fmt.Errorf("no such group: %s", req.GetGroup())
+	}
+	view, ttl, err := group.getWithExpire(req.GetKey())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Response{Value: view.ByteSlice(), TtlMillis: ttl.Milliseconds()}, nil
+}
+
+func (s *groupCacheServer) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	s.pool.Log("DELETE %s %s", req.GetGroup(), req.GetKey())
+	group := GetGroup(req.GetGroup())
+	if group == nil {
+		return nil, fmt.Errorf("no such group: %s", req.GetGroup())
+	}
+	// 这是某个节点广播过来的删除请求，只需在本地生效，不需要再次广播。
+	group.removeLocally(req.GetKey())
+	return &pb.DeleteResponse{Ok: true}, nil
+}
+
+// grpcGetter 实现 PeerGetter，每个远程节点复用同一条 grpc.ClientConn，
+// 避免每次 Get 都重新建立连接、重走一遍握手。
+type grpcGetter struct {
+	addr string
+
+	mu   sync.Mutex
+	conn *grpc.ClientConn
+}
+
+func (g *grpcGetter) client() (pb.GroupCacheClient, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn == nil {
+		conn, err := grpc.Dial(g.addr, grpc.WithInsecure())
+		if err != nil {
+			return nil, err
+		}
+		g.conn = conn
+	}
+	return pb.NewGroupCacheClient(g.conn), nil
+}
+
+func (g *grpcGetter) Get(in *pb.Request, out *pb.Response) error {
+	client, err := g.client()
+	if err != nil {
+		return err
+	}
+	res, err := client.Get(context.Background(), in)
+	if err != nil {
+		return err
+	}
+	*out = *res
+	return nil
+}
+
+// delete 请求该节点清理自己的本地缓存，ctx 通常带有 broadcastTimeout 超时，
+// 防止单个失联节点拖慢广播。
+func (g *grpcGetter) delete(ctx context.Context, group, key string) error {
+	client, err := g.client()
+	if err != nil {
+		return err
+	}
+	_, err = client.Delete(ctx, &pb.DeleteRequest{Group: group, Key: key})
+	return err
+}
+
+var _ PeerGetter = (*grpcGetter)(nil)
@@ -3,6 +3,7 @@ package cache
 import (
 	pb "Cache/cache/cachepb/cachepb"
 	"Cache/cache/consistenthash"
+	"Cache/cache/discovery"
 	"fmt"
 	"github.com/golang/protobuf/proto"
 	"log"
@@ -59,13 +60,20 @@ func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	view, err := group.Get(key)
+	if r.Method == http.MethodDelete {
+		// 这是某个节点广播过来的删除请求，只需在本地生效，不需要再次广播。
+		group.removeLocally(key)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	view, ttl, err := group.getWithExpire(key)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	body, err := proto.Marshal(&pb.Response{Value: view.ByteSlice()})
+	body, err := proto.Marshal(&pb.Response{Value: view.ByteSlice(), TtlMillis: ttl.Milliseconds()})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -75,15 +83,63 @@ func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
+// Set 更新节点列表。与旧的节点集合相比，只对新增/移除的节点分别调用
+// Add/Remove，而不是重建整个哈希环，这样一次滚动的成员变更只会重新映射
+// 大约 1/N 的 key，而不是全部。
 func (p *HTTPPool) Set(peers ...string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.peers = consistenthash.New(defaultReplicate, nil)
-	p.peers.Add(peers...)
-	p.httpGetters = make(map[string]*httpGetter, len(peers))
+
+	if p.peers == nil {
+		p.peers = consistenthash.New(defaultReplicate, nil)
+		p.httpGetters = make(map[string]*httpGetter, len(peers))
+	}
+
+	newSet := make(map[string]struct{}, len(peers))
+	for _, peer := range peers {
+		newSet[peer] = struct{}{}
+	}
+
+	var removed []string
+	for peer := range p.httpGetters {
+		if _, ok := newSet[peer]; !ok {
+			removed = append(removed, peer)
+		}
+	}
+	if len(removed) > 0 {
+		p.peers.Remove(removed...)
+		for _, peer := range removed {
+			delete(p.httpGetters, peer)
+		}
+	}
+
+	var added []string
 	for _, peer := range peers {
-		p.httpGetters[peer] = &httpGetter{baseURL: peer + p.basePath}
+		if _, ok := p.httpGetters[peer]; !ok {
+			added = append(added, peer)
+		}
 	}
+	if len(added) > 0 {
+		p.peers.Add(added...)
+		for _, peer := range added {
+			p.httpGetters[peer] = &httpGetter{baseURL: peer + p.basePath}
+		}
+	}
+}
+
+// RunWithRegistry 把自己注册进 reg，并持续消费 reg.Watch() 推送的最新节点
+// 列表，将其交给 Set 做增量更新；Set 本身已经持有 p.mu 并按需 Add/Remove，
+// 因此这里不需要重新实现一遍哈希环维护逻辑。调用方通常在一个单独的 goroutine
+// 中运行它，直到注册中心连接被关闭、Watch 返回的 channel 关闭为止。
+func (p *HTTPPool) RunWithRegistry(reg discovery.PeerRegistry) error {
+	if err := reg.Register(p.self); err != nil {
+		return err
+	}
+	for peers := range reg.Watch() {
+		p.Log("peers changed: %v", peers)
+		p.Set(peers...)
+	}
+	return nil
 }
 
 func (p *HTTPPool) PickPeer(key string) (PeerGetter, bool) {
@@ -96,4 +152,36 @@ func (p *HTTPPool) PickPeer(key string) (PeerGetter, bool) {
 	return nil, false
 }
 
+// Broadcast 向除自身外的所有已知节点发送删除请求，采用尽力而为策略：单个
+// 节点超时或失败不影响其余节点的广播，调用方可以从返回值中看到每个节点各自
+// 的结果。
+func (p *HTTPPool) Broadcast(group, key string) map[string]error {
+	p.mu.Lock()
+	getters := make(map[string]*httpGetter, len(p.httpGetters))
+	for peer, getter := range p.httpGetters {
+		if peer == p.self {
+			continue
+		}
+		getters[peer] = getter
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make(map[string]error, len(getters))
+	for peer, getter := range getters {
+		wg.Add(1)
+		go func(peer string, getter *httpGetter) {
+			defer wg.Done()
+			err := getter.Delete(group, key)
+			mu.Lock()
+			errs[peer] = err
+			mu.Unlock()
+		}(peer, getter)
+	}
+	wg.Wait()
+	return errs
+}
+
 var _ PeerPicker = (*HTTPPool)(nil)
+var _ PeerBroadcaster = (*HTTPPool)(nil)
@@ -0,0 +1,129 @@
+package lru
+
+import (
+	"container/list"
+	"time"
+)
+
+// Cache 是一个 LRU 缓存，并发不安全，由调用方自行加锁。
+// 维护一个双向链表 ll，最近访问的节点放在队首，淘汰时从队尾删除。
+type Cache struct {
+	maxBytes int64
+	nbytes   int64
+	ll       *list.List
+	cache    map[string]*list.Element
+	// OnEvicted 在某条记录被淘汰时调用（可选），过期记录被清理时也会触发。
+	OnEvicted func(key string, value Value)
+}
+
+type entry struct {
+	key   string
+	value Value
+	// expireAt 是该记录的过期时间，零值表示永不过期。
+	expireAt time.Time
+}
+
+func (e *entry) expired() bool {
+	return !e.expireAt.IsZero() && time.Now().After(e.expireAt)
+}
+
+// Value 使用 Len 计算其所占用的字节数。
+type Value interface {
+	Len() int
+}
+
+// New 创建一个 Cache 实例，maxBytes 为 0 表示不限制内存大小。
+func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
+	return &Cache{
+		maxBytes:  maxBytes,
+		ll:        list.New(),
+		cache:     make(map[string]*list.Element),
+		OnEvicted: onEvicted,
+	}
+}
+
+// Get 查找 key 对应的值。若记录已过期，将其当作未命中处理并顺带清理掉，
+// 否则将该节点移动到队首。
+func (c *Cache) Get(key string) (value Value, ok bool) {
+	value, _, ok = c.GetWithExpire(key)
+	return
+}
+
+// GetWithExpire 与 Get 相同，但额外返回该记录的过期时间（零值表示不过期），
+// 供需要把剩余 TTL 转发给调用方的场景使用（例如节点间通信把本地剩余 TTL
+// 传给发起请求的一方，使其镜像进 hotCache 时不会丢失陈旧度保证）。
+func (c *Cache) GetWithExpire(key string) (value Value, expireAt time.Time, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		if kv.expired() {
+			c.removeElement(ele)
+			return nil, time.Time{}, false
+		}
+		c.ll.MoveToFront(ele)
+		return kv.value, kv.expireAt, true
+	}
+	return
+}
+
+// Remove 删除 key 对应的记录（若存在），用于主动失效而非被动淘汰。
+func (c *Cache) Remove(key string) {
+	if ele, ok := c.cache[key]; ok {
+		c.removeElement(ele)
+	}
+}
+
+// RemoveOldest 淘汰队尾（最久未使用）的记录。
+func (c *Cache) RemoveOldest() {
+	ele := c.ll.Back()
+	if ele != nil {
+		c.removeElement(ele)
+	}
+}
+
+func (c *Cache) removeElement(ele *list.Element) {
+	c.ll.Remove(ele)
+	kv := ele.Value.(*entry)
+	delete(c.cache, kv.key)
+	c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+}
+
+// Add 新增或更新 key 对应的值，不设置过期时间，超出 maxBytes 时持续淘汰队尾记录。
+func (c *Cache) Add(key string, value Value) {
+	c.add(key, value, time.Time{})
+}
+
+// AddWithExpire 与 Add 相同，但额外记录 expireAt，到期后该记录在 Get 时
+// 会被当作未命中处理。
+func (c *Cache) AddWithExpire(key string, value Value, expireAt time.Time) {
+	c.add(key, value, expireAt)
+}
+
+func (c *Cache) add(key string, value Value, expireAt time.Time) {
+	if ele, ok := c.cache[key]; ok {
+		c.ll.MoveToFront(ele)
+		kv := ele.Value.(*entry)
+		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
+		kv.value = value
+		kv.expireAt = expireAt
+	} else {
+		ele := c.ll.PushFront(&entry{key: key, value: value, expireAt: expireAt})
+		c.cache[key] = ele
+		c.nbytes += int64(len(key)) + int64(value.Len())
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		c.RemoveOldest()
+	}
+}
+
+// Len 返回当前缓存的记录数。
+func (c *Cache) Len() int {
+	return c.ll.Len()
+}
+
+// Bytes 返回当前缓存占用的字节数。
+func (c *Cache) Bytes() int64 {
+	return c.nbytes
+}
@@ -7,8 +7,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
+// broadcastTimeout 限制删除广播中单个节点的等待时间，避免一个失联节点拖慢
+// 或挂起整次 Remove 调用。
+const broadcastTimeout = 3 * time.Second
+
 // 注册节点(Register Peers)，借助一致性哈希算法选择节点。
 // 实现 HTTP 客户端，与远程节点的服务端通信
 
@@ -16,6 +21,14 @@ type PeerPicker interface {
 	PickPeer(key string) (peer PeerGetter, ok bool)
 }
 
+// PeerBroadcaster 是 PeerPicker 的一个可选扩展：把某个 group/key 的删除动作
+// 尽力广播给除自身以外的所有已知节点。不是所有 Pool 都需要实现它——
+// Group.Remove 在注册的 PeerPicker 没有实现该接口时，退化为只在本地生效。
+// 返回值按节点地址汇总每个节点各自的失败原因（成功的节点对应 nil）。
+type PeerBroadcaster interface {
+	Broadcast(group, key string) map[string]error
+}
+
 type PeerGetter interface {
 	//Get(group string, key string) ([]byte, error)
 	Get(in *pb.Request, out *pb.Response) error
@@ -49,3 +62,27 @@ func (h *httpGetter) Get(in *pb.Request, out *pb.Response) error {
 	}
 	return nil
 }
+
+// Delete 向该节点发出 DELETE /<basePath>/<group>/<key>，请求它清理自己的
+// 本地缓存。请求带有 broadcastTimeout 超时，防止单个失联节点拖慢广播。
+func (h *httpGetter) Delete(group, key string) error {
+	u := fmt.Sprintf("%v%v/%v",
+		h.baseURL,
+		url.QueryEscape(group),
+		url.QueryEscape(key))
+	req, err := http.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: broadcastTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("server returned: %v", res.Status)
+	}
+	return nil
+}
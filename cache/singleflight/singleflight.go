@@ -0,0 +1,45 @@
+package singleflight
+
+import "sync"
+
+// call 代表一次正在进行中、或已经结束的 Do 调用。
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// Group 将针对同一个 key 的并发调用合并为一次实际执行，防止热点 key
+// 过期瞬间大量请求同时穿透到 getter 回调或远程节点，造成缓存击穿。
+type Group struct {
+	mu sync.Mutex
+	m  map[string]*call
+}
+
+// Do 对同一个 key，保证 fn 在同一时刻只会被执行一次：
+// 第一个到达的 goroutine 负责执行 fn 并把结果广播给其余等待者；
+// 其余 goroutine 只需等待并复用该结果。
+func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	if c, ok := g.m[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := new(call)
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.m, key)
+	g.mu.Unlock()
+
+	return c.val, c.err
+}
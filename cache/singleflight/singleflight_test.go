@@ -0,0 +1,124 @@
+package singleflight
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestDoCoalescesConcurrentCalls verifies the core guarantee this package
+// exists for: while one Do("key", ...) call is in flight, other Do calls for
+// the same key must not re-run fn and must instead share its result.
+func TestDoCoalescesConcurrentCalls(t *testing.T) {
+	var g Group
+	var calls int32
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var first sync.WaitGroup
+	first.Add(1)
+	var firstResult interface{}
+	var firstErr error
+	go func() {
+		defer first.Done()
+		firstResult, firstErr = g.Do("key", func() (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			close(started)
+			<-release
+			return "value", nil
+		})
+	}()
+	<-started // the first call is now in flight and blocked inside fn
+
+	const n = 20
+	var followers sync.WaitGroup
+	results := make([]interface{}, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		followers.Add(1)
+		go func(i int) {
+			defer followers.Done()
+			results[i], errs[i] = g.Do("key", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				return "should not run", nil
+			})
+		}(i)
+	}
+
+	// Give the followers time to reach Do and start waiting on the in-flight
+	// call before releasing it.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	first.Wait()
+	followers.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("fn called %d times while a call for the same key was in flight, want 1", got)
+	}
+	if firstErr != nil {
+		t.Fatalf("unexpected error: %v", firstErr)
+	}
+	if firstResult != "value" {
+		t.Fatalf("got %v, want %q", firstResult, "value")
+	}
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("follower %d: unexpected error %v", i, errs[i])
+		}
+		if results[i] != "value" {
+			t.Fatalf("follower %d: got %v, want the in-flight call's result %q", i, results[i], "value")
+		}
+	}
+}
+
+func TestDoDoesNotCacheAcrossCalls(t *testing.T) {
+	var g Group
+	var calls int32
+
+	for i := 0; i < 3; i++ {
+		if _, err := g.Do("key", func() (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, nil
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls != 3 {
+		t.Fatalf("fn called %d times across sequential Do calls, want 3 (a completed call must not be cached)", calls)
+	}
+}
+
+func TestDoReturnsError(t *testing.T) {
+	var g Group
+	wantErr := errors.New("boom")
+
+	_, err := g.Do("key", func() (interface{}, error) {
+		return nil, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("got error %v, want %v", err, wantErr)
+	}
+}
+
+func TestDoKeysAreIndependent(t *testing.T) {
+	var g Group
+	var calls int32
+
+	fn := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, nil
+	}
+	if _, err := g.Do("a", fn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := g.Do("b", fn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("fn called %d times for two distinct keys, want 2", calls)
+	}
+}